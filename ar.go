@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -42,11 +43,12 @@ func (f *fileInfo) Sys() interface{}   { return nil }
 
 // Reader can read ar archives
 type Reader struct {
-	buffer  *bufio.Reader
-	valid   bool
-	err     error
-	section io.LimitedReader
-	hslice  []byte
+	buffer    *bufio.Reader
+	valid     bool
+	err       error
+	section   io.LimitedReader
+	hslice    []byte
+	longNames *longNameTable
 }
 
 // Reset cancels all internal state/buffering and starts to read from in.
@@ -56,6 +58,7 @@ func (r *Reader) Reset(in io.Reader) {
 	r.valid = false
 	r.err = nil
 	r.section.R, r.section.N = nil, 0
+	r.longNames = nil
 }
 
 // NewReader will start parsing a possible archive from r
@@ -79,8 +82,9 @@ func (r *Reader) flush_section() error {
 	}
 
 	if r.section.N > 0 {
-		_, err := io.Copy(ioutil.Discard, &r.section)
-		return r.stick(err)
+		if _, err := io.Copy(ioutil.Discard, &r.section); err != nil {
+			return r.stick(err)
+		}
 	}
 	// skip padding byte.
 	if c, err := r.buffer.ReadByte(); err != nil {
@@ -95,6 +99,13 @@ func (r *Reader) flush_section() error {
 
 // Next will advance to the next available file in the archive and return it's meta data.
 // After calling r.Next, you can use r.Read() to actually read the file contained.
+//
+// Next transparently resolves the two common long filename conventions:
+// the GNU/SysV "//" string table (with "/N" members referencing an offset
+// into it) and the BSD "#1/N" convention (which stores the real name in
+// the first N bytes of the member's data). The GNU/SysV symbol table
+// member ("/") is skipped automatically, since it carries no filename of
+// its own.
 func (r *Reader) Next() (os.FileInfo, error) {
 	if r.err != nil {
 		return nil, r.err
@@ -107,22 +118,133 @@ func (r *Reader) Next() (os.FileInfo, error) {
 		r.valid = true
 	}
 
-	if r.section.R != nil {
-		if err := r.flush_section(); err != nil {
+	for {
+		if r.section.R != nil {
+			if err := r.flush_section(); err != nil {
+				return nil, r.stick(err)
+			}
+		}
+
+		if _, err := io.ReadFull(r.buffer, r.hslice); err != nil {
+			return nil, r.stick(err)
+		}
+
+		fi, err := parseFileHeader(r.hslice)
+		if err != nil {
 			return nil, r.stick(err)
 		}
+
+		switch {
+		case fi.name == "/":
+			// GNU/SysV symbol table: skippable, carries no filename.
+			r.section.R, r.section.N = r.buffer, fi.Size()
+			continue
+
+		case fi.name == "//":
+			if err := r.readStringTable(fi.Size()); err != nil {
+				return nil, r.stick(err)
+			}
+			continue
+
+		case strings.HasPrefix(fi.name, "/") && isDigits(fi.name[1:]):
+			name, err := r.resolveGNUName(fi.name[1:])
+			if err != nil {
+				return nil, r.stick(err)
+			}
+			fi.name = name
+			r.section.R, r.section.N = r.buffer, fi.Size()
+			return fi, nil
+
+		case strings.HasPrefix(fi.name, "#1/"):
+			if err := r.resolveBSDName(fi); err != nil {
+				return nil, r.stick(err)
+			}
+			r.section.R, r.section.N = r.buffer, fi.Size()
+			return fi, nil
+
+		default:
+			r.section.R, r.section.N = r.buffer, fi.Size()
+			return fi, nil
+		}
+	}
+}
+
+// readStringTable consumes the GNU/SysV "//" member and keeps its content
+// around so later "/N" members can be resolved to their real name.
+func (r *Reader) readStringTable(size int64) error {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.buffer, data); err != nil {
+		return err
+	}
+	if size%2 != 0 {
+		if c, err := r.buffer.ReadByte(); err != nil {
+			return err
+		} else if c != '\n' {
+			r.buffer.UnreadByte()
+		}
 	}
+	r.longNames = &longNameTable{data: data}
+	return nil
+}
 
-	if _, err := io.ReadFull(r.buffer, r.hslice); err != nil {
-		return nil, r.stick(err)
+func (r *Reader) resolveGNUName(offsetField string) (string, error) {
+	if r.longNames == nil {
+		return "", CorruptArchiveError("long name reference without a preceding string table")
 	}
+	offset, err := strconv.ParseInt(offsetField, 10, 64)
+	if err != nil {
+		return "", CorruptArchiveError(err.Error())
+	}
+	return r.longNames.name(offset)
+}
 
-	fi, err := parseFileHeader(r.hslice)
+// resolveBSDName reads the real, embedded name of a BSD "#1/N" member and
+// adjusts fi to describe just the payload that follows it.
+func (r *Reader) resolveBSDName(fi *fileInfo) error {
+	n, err := strconv.ParseInt(fi.name[len("#1/"):], 10, 64)
 	if err != nil {
-		return nil, r.stick(err)
+		return CorruptArchiveError(err.Error())
 	}
-	r.section.R, r.section.N = r.buffer, fi.Size()
-	return fi, nil
+	if n < 0 || n > fi.size {
+		return CorruptArchiveError("invalid BSD long name length")
+	}
+
+	name := make([]byte, n)
+	if _, err := io.ReadFull(r.buffer, name); err != nil {
+		return err
+	}
+	fi.name = string(bytes.TrimRight(name, "\x00"))
+	fi.size -= n
+	return nil
+}
+
+// longNameTable holds the contents of a GNU/SysV "//" member, a blob of
+// "name/\n"-terminated entries addressed by byte offset from "/N" members.
+type longNameTable struct {
+	data []byte
+}
+
+func (t *longNameTable) name(offset int64) (string, error) {
+	if offset < 0 || offset >= int64(len(t.data)) {
+		return "", CorruptArchiveError("long name offset out of range")
+	}
+	end := bytes.IndexAny(t.data[offset:], "/\n")
+	if end < 0 {
+		return "", CorruptArchiveError("long name not terminated")
+	}
+	return string(t.data[offset : offset+int64(end)]), nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
 }
 
 func (r *Reader) Read(b []byte) (n int, err error) {
@@ -151,7 +273,27 @@ func (c CorruptArchiveError) Error() string {
 	return "corrupt archive: " + string(c)
 }
 
+// parseHeaderInt parses a numeric header field, treating a field left blank
+// (as real GNU ar does for the timestamp/uid/gid/mode of its "//" string
+// table member) as 0 instead of raising a parse error.
+func parseHeaderInt(field []byte) (int64, error) {
+	s := string(bytes.TrimSpace(field))
+	if s == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, CorruptArchiveError(err.Error())
+	}
+	return n, nil
+}
+
 func parseFileMode(s string) (filemode os.FileMode, err error) {
+	if s == "" {
+		return 0, nil
+	}
+
 	mode, err := strconv.ParseUint(s, 8, 32)
 	if err != nil {
 		return filemode, CorruptArchiveError(err.Error())
@@ -181,9 +323,9 @@ func parseFileHeader(header []byte) (*fileInfo, error) {
 	}
 
 	name := string(bytes.TrimSpace(header[0:16]))
-	secs, err := strconv.ParseInt(string(bytes.TrimSpace(header[16:16+12])), 10, 64)
+	secs, err := parseHeaderInt(header[16 : 16+12])
 	if err != nil {
-		return nil, CorruptArchiveError(err.Error())
+		return nil, err
 	}
 
 	filemode, err := parseFileMode(string(bytes.TrimSpace(header[40 : 40+8])))
@@ -191,9 +333,9 @@ func parseFileHeader(header []byte) (*fileInfo, error) {
 		return nil, err
 	}
 
-	filesize, err := strconv.ParseInt(string(bytes.TrimSpace(header[48:48+10])), 10, 64)
+	filesize, err := parseHeaderInt(header[48 : 48+10])
 	if err != nil {
-		return nil, CorruptArchiveError(err.Error())
+		return nil, err
 	}
 
 	fi := &fileInfo{
@@ -355,3 +355,207 @@ func BenchmarkWriterBigFiles(b *testing.B) {
 func BenchmarkWriterManySmallFiles(b *testing.B) {
 	benchmarkWriter(b, 1024, 8)
 }
+
+func TestReaderLongNamesGNU(t *testing.T) {
+	table := "really-long-name.txt/\n"
+	buf := new(bytes.Buffer)
+	buf.WriteString(magic)
+	buf.Write(formatHeader("//", time.Unix(0, 0), 0644, int64(len(table))))
+	buf.WriteString(table)
+	buf.Write(formatHeader("/0", time.Unix(1385068169, 0), os.FileMode(0644), 5))
+	buf.WriteString("hello\n")
+
+	r := NewReader(buf)
+	fi, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Name() != "really-long-name.txt" {
+		t.Errorf("got name %q, want %q", fi.Name(), "really-long-name.txt")
+	}
+	if fi.Size() != 5 {
+		t.Errorf("got size %d, want 5", fi.Size())
+	}
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" {
+		t.Errorf("got content %q, want %q", content, "hello")
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+// blankHeader renders a 60 byte file header the way real GNU ar writes the
+// "//" string table member: name and size set, but timestamp/uid/gid/mode
+// left entirely blank rather than "0".
+func blankHeader(name string, size int64) []byte {
+	hdr := bytes.Repeat([]byte{' '}, 60)
+	copy(hdr[0:16], name)
+	copy(hdr[48:58], strconv.FormatInt(size, 10))
+	copy(hdr[58:60], filemagic)
+	return hdr
+}
+
+func TestReaderLongNamesGNUBlankStringTableHeader(t *testing.T) {
+	table := "really-long-name.txt/\n"
+	buf := new(bytes.Buffer)
+	buf.WriteString(magic)
+	buf.Write(blankHeader("//", int64(len(table))))
+	buf.WriteString(table)
+	buf.Write(formatHeader("/0", time.Unix(1385068169, 0), os.FileMode(0644), 5))
+	buf.WriteString("hello\n")
+
+	r := NewReader(buf)
+	fi, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Name() != "really-long-name.txt" {
+		t.Errorf("got name %q, want %q", fi.Name(), "really-long-name.txt")
+	}
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" {
+		t.Errorf("got content %q, want %q", content, "hello")
+	}
+}
+
+func TestReaderSkipsOddSizedSymbolTable(t *testing.T) {
+	buf := new(bytes.Buffer)
+	buf.WriteString(magic)
+	buf.Write(formatHeader("/", time.Unix(0, 0), 0644, 3))
+	buf.WriteString("ABC\n")
+	buf.Write(formatHeader("short.txt", time.Unix(1385068169, 0), os.FileMode(0644), 2))
+	buf.WriteString("hi\n")
+
+	r := NewReader(buf)
+	fi, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Name() != "short.txt" {
+		t.Errorf("got name %q, want %q", fi.Name(), "short.txt")
+	}
+}
+
+func TestReaderSkipsSymbolTable(t *testing.T) {
+	buf := new(bytes.Buffer)
+	buf.WriteString(magic)
+	buf.Write(formatHeader("/", time.Unix(0, 0), 0644, 4))
+	buf.WriteString("ABCD")
+	buf.Write(formatHeader("short.txt", time.Unix(1385068169, 0), os.FileMode(0644), 2))
+	buf.WriteString("hi\n")
+
+	r := NewReader(buf)
+	fi, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Name() != "short.txt" {
+		t.Errorf("got name %q, want %q", fi.Name(), "short.txt")
+	}
+}
+
+func TestReaderLongNamesBSD(t *testing.T) {
+	name := "really-long-name.txt"
+	buf := new(bytes.Buffer)
+	buf.WriteString(magic)
+	buf.Write(formatHeader("#1/"+strconv.Itoa(len(name)), time.Unix(1385068169, 0), os.FileMode(0644), int64(len(name))+5))
+	buf.WriteString(name)
+	buf.WriteString("hello\n")
+
+	r := NewReader(buf)
+	fi, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Name() != "really-long-name.txt" {
+		t.Errorf("got name %q, want %q", fi.Name(), "really-long-name.txt")
+	}
+	if fi.Size() != 5 {
+		t.Errorf("got size %d, want 5", fi.Size())
+	}
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" {
+		t.Errorf("got content %q, want %q", content, "hello")
+	}
+}
+
+func TestWriterLongNamesBSDAutoUpgrade(t *testing.T) {
+	b := new(bytes.Buffer)
+	w := NewWriter(b)
+	fi := &fileInfo{
+		name:  "really-long-name.txt",
+		mtime: time.Unix(1385068169, 0),
+		mode:  os.FileMode(0644),
+		size:  5,
+	}
+	if _, err := w.WriteFile(fi, strings.NewReader("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReader(b)
+	got, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name() != fi.name {
+		t.Errorf("got name %q, want %q", got.Name(), fi.name)
+	}
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" {
+		t.Errorf("got content %q, want %q", content, "hello")
+	}
+}
+
+func TestWriterLongNamesGNU(t *testing.T) {
+	b := new(bytes.Buffer)
+	w := NewWriter(b, WithMode(ModeGNU))
+
+	files := []*fileInfo{
+		{name: "short.txt", mtime: time.Unix(1385068169, 0), mode: os.FileMode(0644), size: 2},
+		{name: "really-long-name.txt", mtime: time.Unix(1385068169, 0), mode: os.FileMode(0644), size: 5},
+	}
+	contents := []string{"hi", "hello"}
+
+	for i, fi := range files {
+		if _, err := w.WriteFile(fi, strings.NewReader(contents[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReader(b)
+	for i, want := range files {
+		got, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name() != want.name {
+			t.Errorf("%d: got name %q, want %q", i, got.Name(), want.name)
+		}
+		content, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != contents[i] {
+			t.Errorf("%d: got content %q, want %q", i, content, contents[i])
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
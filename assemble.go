@@ -0,0 +1,128 @@
+package ar
+
+import (
+	"bufio"
+	"encoding/gob"
+	"io"
+	"io/ioutil"
+)
+
+// MemberRecord captures everything about one archive member that
+// Disassemble cannot recompute from its payload alone: the raw 60 byte
+// header exactly as it appeared on disk, and whether a padding byte
+// followed the payload (and if so, its value — GNU ar pads with '\n', but
+// archives produced by other tools have been seen using other bytes).
+type MemberRecord struct {
+	Name    string // raw header name, used to look up the payload on Reassemble
+	Header  [60]byte
+	Padded  bool
+	PadByte byte
+}
+
+// ArchiveMetadata is the side-metadata captured by Disassemble: enough to
+// reproduce an ar archive byte-for-byte given each member's payload, without
+// this package needing to understand every header convention a producer
+// might have used. Encode it with encoding/gob to store or transmit it.
+type ArchiveMetadata struct {
+	Leading  []byte // bytes before the first member; always the global magic
+	Members  []MemberRecord
+	Trailing []byte // bytes after the last member, not covered by any header
+
+	// Trailing is only filled when fewer than 60 bytes follow the last
+	// member; a longer tail is parsed as a member header, and Disassemble
+	// returns that parse error.
+}
+
+// Disassemble scans r, an ar archive, and returns the metadata needed to
+// reproduce it byte-for-byte via Reassemble. It does not interpret member
+// names beyond the raw 16 byte header field, so it works unchanged on
+// archives using conventions this package does not otherwise parse.
+func Disassemble(r io.Reader) (*ArchiveMetadata, error) {
+	br := bufio.NewReader(r)
+
+	leading := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, leading); err != nil {
+		return nil, err
+	}
+	if string(leading) != magic {
+		return nil, CorruptArchiveError("global archive header not found")
+	}
+
+	am := &ArchiveMetadata{Leading: leading}
+
+	for {
+		var hdr [60]byte
+		n, err := io.ReadFull(br, hdr[:])
+		if err == io.EOF {
+			break
+		}
+		if err == io.ErrUnexpectedEOF {
+			am.Trailing = append([]byte(nil), hdr[:n]...)
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fi, err := parseFileHeader(hdr[:])
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := io.CopyN(ioutil.Discard, br, fi.Size()); err != nil {
+			return nil, err
+		}
+
+		rec := MemberRecord{Name: fi.name, Header: hdr}
+		if fi.Size()%2 != 0 {
+			pad, err := br.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			rec.Padded = true
+			rec.PadByte = pad
+		}
+		am.Members = append(am.Members, rec)
+	}
+
+	return am, nil
+}
+
+// Reassemble decodes an ArchiveMetadata previously written with
+// encoding/gob from meta, then reproduces the original archive byte for
+// byte into out: each member's preserved header is written verbatim,
+// followed by its payload as supplied by payloads (looked up by the
+// member's raw header name) and the original padding byte, if any.
+func Reassemble(meta io.Reader, payloads func(name string) (io.Reader, int64, error), out io.Writer) error {
+	var am ArchiveMetadata
+	if err := gob.NewDecoder(meta).Decode(&am); err != nil {
+		return err
+	}
+
+	if _, err := out.Write(am.Leading); err != nil {
+		return err
+	}
+
+	for _, rec := range am.Members {
+		if _, err := out.Write(rec.Header[:]); err != nil {
+			return err
+		}
+
+		payload, size, err := payloads(rec.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.CopyN(out, payload, size); err != nil {
+			return err
+		}
+
+		if rec.Padded {
+			if _, err := out.Write([]byte{rec.PadByte}); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := out.Write(am.Trailing)
+	return err
+}
@@ -0,0 +1,54 @@
+package ar
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDisassembleReassembleRoundTrip(t *testing.T) {
+	payloads := map[string]string{
+		"debian-binary":  "2.0\n",
+		"control.tar.gz": "",
+	}
+
+	am, err := Disassemble(strings.NewReader(testCommon))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(am.Members) != 2 {
+		t.Fatalf("got %d members, want 2", len(am.Members))
+	}
+
+	var encoded bytes.Buffer
+	if err := gob.NewEncoder(&encoded).Encode(am); err != nil {
+		t.Fatal(err)
+	}
+
+	lookup := func(name string) (io.Reader, int64, error) {
+		data := payloads[name]
+		return strings.NewReader(data), int64(len(data)), nil
+	}
+
+	var out bytes.Buffer
+	if err := Reassemble(&encoded, lookup, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != testCommon {
+		t.Errorf("got\n%q\nwant\n%q", out.String(), testCommon)
+	}
+}
+
+func TestDisassembleTrailingGarbage(t *testing.T) {
+	archive := testCommon + "trailing junk"
+	am, err := Disassemble(strings.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(am.Trailing) != "trailing junk" {
+		t.Errorf("got trailing %q, want %q", am.Trailing, "trailing junk")
+	}
+}
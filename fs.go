@@ -0,0 +1,173 @@
+package ar
+
+import (
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FS presents r as an io/fs.FS. Since ar archives have no concept of
+// directories, every member appears as a plain file inside a single
+// synthetic root directory.
+func (r *IndexedReader) FS() fs.FS {
+	return &arFS{ir: r}
+}
+
+// OpenFS scans ra, exactly like NewIndexedReader, and presents the result
+// as an io/fs.FS.
+func OpenFS(ra io.ReaderAt, size int64) (fs.FS, error) {
+	ir, err := NewIndexedReader(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	return ir.FS(), nil
+}
+
+type arFS struct {
+	ir *IndexedReader
+}
+
+var (
+	_ fs.FS         = (*arFS)(nil)
+	_ fs.ReadFileFS = (*arFS)(nil)
+	_ fs.StatFS     = (*arFS)(nil)
+	_ fs.ReadDirFS  = (*arFS)(nil)
+)
+
+// lookup resolves name to an index into ir.Files(), or -1 for the
+// synthetic root directory ".". ar has no nested paths, so anything beyond
+// a bare member name or "." is rejected.
+func (a *arFS) lookup(op, name string) (int, error) {
+	if name == "." {
+		return -1, nil
+	}
+	if !fs.ValidPath(name) || strings.Contains(name, "/") {
+		return -1, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	i, ok := a.ir.byName[name]
+	if !ok {
+		return -1, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return i, nil
+}
+
+func (a *arFS) Open(name string) (fs.File, error) {
+	i, err := a.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 {
+		return &arDir{entries: a.dirEntries()}, nil
+	}
+	fh := a.ir.files[i]
+	return &arFile{
+		sr:   io.NewSectionReader(a.ir.ra, fh.DataOffset, fh.Size),
+		info: fileInfoFor(fh),
+	}, nil
+}
+
+func (a *arFS) ReadFile(name string) ([]byte, error) {
+	i, err := a.lookup("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+	fh := a.ir.files[i]
+	sr := io.NewSectionReader(a.ir.ra, fh.DataOffset, fh.Size)
+	buf := make([]byte, fh.Size)
+	if _, err := io.ReadFull(sr, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (a *arFS) Stat(name string) (fs.FileInfo, error) {
+	i, err := a.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 {
+		return rootDirInfo{}, nil
+	}
+	return fileInfoFor(a.ir.files[i]), nil
+}
+
+func (a *arFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	i, err := a.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if i >= 0 {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return a.dirEntries(), nil
+}
+
+func (a *arFS) dirEntries() []fs.DirEntry {
+	entries := make([]fs.DirEntry, len(a.ir.files))
+	for i, fh := range a.ir.files {
+		entries[i] = fs.FileInfoToDirEntry(fileInfoFor(fh))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries
+}
+
+func fileInfoFor(fh FileHeader) *fileInfo {
+	return &fileInfo{name: fh.Name, mtime: fh.ModTime, mode: fh.Mode, size: fh.Size}
+}
+
+// arFile backs an open ar member; Read is cheap since it's just an
+// io.SectionReader over the shared ReaderAt.
+type arFile struct {
+	sr   *io.SectionReader
+	info fs.FileInfo
+}
+
+func (f *arFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *arFile) Read(b []byte) (int, error) { return f.sr.Read(b) }
+func (f *arFile) Close() error               { return nil }
+
+// arDir is the synthetic root directory every member lives in.
+type arDir struct {
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *arDir) Stat() (fs.FileInfo, error) { return rootDirInfo{}, nil }
+func (d *arDir) Close() error               { return nil }
+
+func (d *arDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: ".", Err: fs.ErrInvalid}
+}
+
+func (d *arDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := len(d.entries) - d.offset
+	if n <= 0 {
+		entries := d.entries[d.offset:]
+		d.offset = len(d.entries)
+		return entries, nil
+	}
+	if remaining == 0 {
+		return nil, io.EOF
+	}
+	if n > remaining {
+		n = remaining
+	}
+	entries := d.entries[d.offset : d.offset+n]
+	d.offset += n
+	return entries, nil
+}
+
+// rootDirInfo describes the synthetic root directory.
+type rootDirInfo struct{}
+
+func (rootDirInfo) Name() string       { return "." }
+func (rootDirInfo) Size() int64        { return 0 }
+func (rootDirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (rootDirInfo) ModTime() time.Time { return time.Time{} }
+func (rootDirInfo) IsDir() bool        { return true }
+func (rootDirInfo) Sys() interface{}   { return nil }
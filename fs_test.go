@@ -0,0 +1,55 @@
+package ar
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSBasics(t *testing.T) {
+	ra := buildTestArchive(t)
+	afs, err := OpenFS(ra, ra.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := fs.ReadFile(afs, "debian-binary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "2.0\n" {
+		t.Errorf("got %q, want %q", content, "2.0\n")
+	}
+
+	entries, err := fs.ReadDir(afs, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{"control.tar.gz", "debian-binary"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("got %v, want %v", names, want)
+	}
+
+	if err := fstest.TestFS(afs, "debian-binary", "control.tar.gz"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFSRejectsNestedPaths(t *testing.T) {
+	ra := buildTestArchive(t)
+	afs, err := OpenFS(ra, ra.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := afs.Open("a/b"); err == nil {
+		t.Error("expected error opening nested path")
+	}
+	if _, err := afs.Open("../escape"); err == nil {
+		t.Error("expected error opening path with ..")
+	}
+}
@@ -0,0 +1,185 @@
+package ar
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileHeader describes a single member of an archive indexed by an
+// IndexedReader. Name is already resolved to its real value for archives
+// using the GNU/SysV "//" or BSD "#1/N" long filename conventions.
+type FileHeader struct {
+	Name         string
+	HeaderOffset int64
+	DataOffset   int64
+	Size         int64
+	ModTime      time.Time
+	Mode         os.FileMode
+}
+
+// IndexedReader provides random access to the members of an ar archive,
+// similar to how archive/zip.Reader indexes a zip file. Unlike Reader, it
+// requires the whole archive to be addressable via a single io.ReaderAt and
+// scans it exactly once, up front, so members can afterwards be opened
+// concurrently and in any order.
+type IndexedReader struct {
+	ra     io.ReaderAt
+	size   int64
+	files  []FileHeader
+	byName map[string]int
+}
+
+// NewIndexedReader scans ra, which must hold size bytes of a valid ar
+// archive, and builds an index of its members. Long names using the
+// GNU/SysV "//" or BSD "#1/N" conventions are resolved during the scan, so
+// Files returns real names throughout.
+func NewIndexedReader(ra io.ReaderAt, size int64) (*IndexedReader, error) {
+	ir := &IndexedReader{ra: ra, size: size}
+	if err := ir.scan(); err != nil {
+		return nil, err
+	}
+	return ir, nil
+}
+
+func (ir *IndexedReader) readAt(off int64, n int64) ([]byte, error) {
+	if off < 0 || n < 0 || off+n > ir.size {
+		return nil, CorruptArchiveError("short archive")
+	}
+	buf := make([]byte, n)
+	if _, err := ir.ra.ReadAt(buf, off); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (ir *IndexedReader) scan() error {
+	m, err := ir.readAt(0, int64(len(magic)))
+	if err != nil {
+		return err
+	}
+	if string(m) != magic {
+		return CorruptArchiveError("global archive header not found")
+	}
+
+	var longNames *longNameTable
+	off := int64(len(magic))
+	for off < ir.size {
+		hdr, err := ir.readAt(off, 60)
+		if err != nil {
+			return CorruptArchiveError("truncated file header")
+		}
+		fi, err := parseFileHeader(hdr)
+		if err != nil {
+			return err
+		}
+
+		dataOffset := off + 60
+		size := fi.Size()
+
+		switch name := fi.name; {
+		case name == "/":
+			// GNU/SysV symbol table: skippable, carries no filename.
+
+		case name == "//":
+			data, err := ir.readAt(dataOffset, size)
+			if err != nil {
+				return err
+			}
+			longNames = &longNameTable{data: data}
+
+		case strings.HasPrefix(name, "/") && isDigits(name[1:]):
+			if longNames == nil {
+				return CorruptArchiveError("long name reference without a preceding string table")
+			}
+			offset, err := strconv.ParseInt(name[1:], 10, 64)
+			if err != nil {
+				return CorruptArchiveError(err.Error())
+			}
+			resolved, err := longNames.name(offset)
+			if err != nil {
+				return err
+			}
+			ir.addFile(resolved, off, dataOffset, size, fi.ModTime(), fi.Mode())
+
+		case strings.HasPrefix(name, "#1/"):
+			n, err := strconv.ParseInt(name[len("#1/"):], 10, 64)
+			if err != nil {
+				return CorruptArchiveError(err.Error())
+			}
+			if n < 0 || n > size {
+				return CorruptArchiveError("invalid BSD long name length")
+			}
+			nameBuf, err := ir.readAt(dataOffset, n)
+			if err != nil {
+				return err
+			}
+			ir.addFile(string(bytes.TrimRight(nameBuf, "\x00")), off, dataOffset+n, size-n, fi.ModTime(), fi.Mode())
+
+		default:
+			ir.addFile(name, off, dataOffset, size, fi.ModTime(), fi.Mode())
+		}
+
+		pad := size % 2
+		off = dataOffset + size + pad
+	}
+	return nil
+}
+
+func (ir *IndexedReader) addFile(name string, headerOffset, dataOffset, size int64, mtime time.Time, mode os.FileMode) {
+	if ir.byName == nil {
+		ir.byName = make(map[string]int)
+	}
+	ir.byName[name] = len(ir.files)
+	ir.files = append(ir.files, FileHeader{
+		Name:         name,
+		HeaderOffset: headerOffset,
+		DataOffset:   dataOffset,
+		Size:         size,
+		ModTime:      mtime,
+		Mode:         mode,
+	})
+}
+
+// Files returns the metadata of every member in the archive, in the order
+// they appear.
+func (ir *IndexedReader) Files() []FileHeader {
+	return ir.files
+}
+
+// OpenAt returns a SectionReader over the payload of the i'th member, as
+// returned by Files. It does not read through any other member first.
+func (ir *IndexedReader) OpenAt(i int) (*io.SectionReader, error) {
+	if i < 0 || i >= len(ir.files) {
+		return nil, os.ErrNotExist
+	}
+	fh := ir.files[i]
+	return io.NewSectionReader(ir.ra, fh.DataOffset, fh.Size), nil
+}
+
+// Open returns the payload of the member with the given name. Callers may
+// open and read several members concurrently; each gets its own
+// io.SectionReader over the shared ReaderAt.
+func (ir *IndexedReader) Open(name string) (io.ReadSeekCloser, error) {
+	i, ok := ir.byName[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	sr, err := ir.OpenAt(i)
+	if err != nil {
+		return nil, err
+	}
+	return &sectionReadCloser{sr}, nil
+}
+
+// sectionReadCloser adapts an io.SectionReader to io.ReadSeekCloser; ar
+// members need no cleanup on Close, since the ReaderAt they're backed by
+// outlives any single member.
+type sectionReadCloser struct {
+	*io.SectionReader
+}
+
+func (sectionReadCloser) Close() error { return nil }
@@ -0,0 +1,143 @@
+package ar
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func buildTestArchive(t *testing.T) *bytes.Reader {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf)
+	debian := &fileInfo{
+		name:  "debian-binary",
+		mtime: time.Unix(1385068169, 0),
+		mode:  os.FileMode(0644),
+		size:  4,
+	}
+	if _, err := w.WriteFile(debian, strings.NewReader("2.0\n")); err != nil {
+		t.Fatal(err)
+	}
+	control := &fileInfo{
+		name:  "control.tar.gz",
+		mtime: time.Unix(1385068169, 0),
+		mode:  os.FileMode(0644),
+		size:  5,
+	}
+	if _, err := w.WriteFile(control, strings.NewReader("data!")); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestIndexedReaderFiles(t *testing.T) {
+	ra := buildTestArchive(t)
+	ir, err := NewIndexedReader(ra, ra.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := ir.Files()
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	if files[0].Name != "debian-binary" || files[0].Size != 4 {
+		t.Errorf("got %+v", files[0])
+	}
+	if files[1].Name != "control.tar.gz" || files[1].Size != 5 {
+		t.Errorf("got %+v", files[1])
+	}
+}
+
+func TestIndexedReaderOpen(t *testing.T) {
+	ra := buildTestArchive(t)
+	ir, err := NewIndexedReader(ra, ra.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Open the second member first, to show it doesn't require draining
+	// the first.
+	f, err := ir.Open("control.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "data!" {
+		t.Errorf("got %q, want %q", content, "data!")
+	}
+	if err := f.Close(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := ir.Open("missing"); err != os.ErrNotExist {
+		t.Errorf("got %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestIndexedReaderOpenAt(t *testing.T) {
+	ra := buildTestArchive(t)
+	ir, err := NewIndexedReader(ra, ra.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sr, err := ir.OpenAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadAll(sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "2.0\n" {
+		t.Errorf("got %q, want %q", content, "2.0\n")
+	}
+
+	if _, err := ir.OpenAt(2); err != os.ErrNotExist {
+		t.Errorf("got %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestIndexedReaderLongNames(t *testing.T) {
+	table := "really-long-name.txt/\n"
+	buf := new(bytes.Buffer)
+	buf.WriteString(magic)
+	buf.Write(formatHeader("//", time.Unix(0, 0), 0644, int64(len(table))))
+	buf.WriteString(table)
+	buf.Write(formatHeader("/0", time.Unix(1385068169, 0), os.FileMode(0644), 5))
+	buf.WriteString("hello\n")
+
+	ra := bytes.NewReader(buf.Bytes())
+	ir, err := NewIndexedReader(ra, ra.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := ir.Files()
+	if len(files) != 1 || files[0].Name != "really-long-name.txt" {
+		t.Fatalf("got %+v", files)
+	}
+
+	f, err := ir.Open("really-long-name.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" {
+		t.Errorf("got %q, want %q", content, "hello")
+	}
+}
+
+var _ io.ReadSeekCloser = (*sectionReadCloser)(nil)
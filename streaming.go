@@ -0,0 +1,192 @@
+package ar
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// defaultMemoryThreshold is how much of a CreateFile payload is buffered in
+// memory, for writers without WriterAt/Seeker support, before spilling to a
+// temp file.
+const defaultMemoryThreshold = 1 << 20 // 1 MiB
+
+// WithMemoryThreshold overrides how many bytes CreateFile buffers in memory
+// before spilling an unknown-size payload to a temp file. It affects
+// writers backed by a plain io.Writer and every ModeGNU writer; other
+// writers on seekable, WriterAt-capable sinks stream straight through and
+// never buffer. A threshold of 0 spills to a temp file immediately.
+func WithMemoryThreshold(n int64) WriterOption {
+	return func(w *Writer) {
+		w.memThreshold = n
+		w.memThresholdSet = true
+	}
+}
+
+// CreateFile returns a writer for a single archive member whose size isn't
+// known up front, such as the output of a compressor. hdr.Size() is
+// ignored; the real size is whatever is written before Close. The header
+// is only finalized on Close, so the returned writer must be closed before
+// any other Writer method is called. Unlike WriteFile, CreateFile only
+// supports names up to 16 bytes, whatever the Writer's mode.
+//
+// If the Writer's underlying sink also implements io.WriterAt and
+// io.Seeker, CreateFile reserves the header, streams the payload directly
+// into the sink, then seeks back and patches the size field and trailing
+// pad byte on Close. Otherwise the payload is buffered in memory up to
+// WithMemoryThreshold bytes, then spilled to a temp file via
+// os.CreateTemp, and the correct header, payload and pad are written out
+// on Close.
+//
+// A ModeGNU Writer always buffers, even onto a WriterAt/Seeker sink: its
+// "//" string table must precede every member that might reference it, so
+// members are only flushed, in call order, on Writer.Close.
+func (w *Writer) CreateFile(hdr os.FileInfo) (io.WriteCloser, error) {
+	if len(hdr.Name()) > 16 {
+		return nil, NotImplementedError("CreateFile only supports names up to 16 bytes")
+	}
+
+	if w.mode != ModeGNU {
+		if wa, ok := w.w.(io.WriterAt); ok {
+			if sk, ok := w.w.(io.Seeker); ok {
+				return w.createFileSeek(hdr, wa, sk)
+			}
+		}
+	}
+	return w.createFileBuffered(hdr)
+}
+
+func (w *Writer) createFileSeek(hdr os.FileInfo, wa io.WriterAt, sk io.Seeker) (io.WriteCloser, error) {
+	if err := w.writeMagic(); err != nil {
+		return nil, err
+	}
+
+	headerOffset, err := sk.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	placeholder := formatHeader(hdr.Name(), hdr.ModTime(), hdr.Mode(), 0)
+	if _, err := w.w.Write(placeholder); err != nil {
+		return nil, err
+	}
+
+	return &seekWriter{wa: wa, sk: sk, hdr: hdr, dataOffset: headerOffset + 60}, nil
+}
+
+// seekWriter streams directly into a WriterAt-capable sink and patches the
+// header in place once the final size is known.
+type seekWriter struct {
+	wa         io.WriterAt
+	sk         io.Seeker
+	hdr        os.FileInfo
+	dataOffset int64
+	size       int64
+	closed     bool
+}
+
+func (s *seekWriter) Write(p []byte) (int, error) {
+	n, err := s.wa.WriteAt(p, s.dataOffset+s.size)
+	s.size += int64(n)
+	return n, err
+}
+
+func (s *seekWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	hdr := formatHeader(s.hdr.Name(), s.hdr.ModTime(), s.hdr.Mode(), s.size)
+	if _, err := s.wa.WriteAt(hdr, s.dataOffset-60); err != nil {
+		return err
+	}
+
+	end := s.dataOffset + s.size
+	if s.size%2 != 0 {
+		if _, err := s.wa.WriteAt([]byte{'\n'}, end); err != nil {
+			return err
+		}
+		end++
+	}
+
+	// Leave the sink positioned right after this member, like WriteFile does.
+	_, err := s.sk.Seek(end, io.SeekStart)
+	return err
+}
+
+func (w *Writer) createFileBuffered(hdr os.FileInfo) (io.WriteCloser, error) {
+	threshold := w.memThreshold
+	if !w.memThresholdSet {
+		threshold = defaultMemoryThreshold
+	}
+	return &bufferedFileWriter{w: w, hdr: hdr, threshold: threshold}, nil
+}
+
+// bufferedFileWriter accumulates a member's payload in memory, spilling to
+// a temp file once it grows past threshold, and flushes header, payload
+// and pad on Close.
+type bufferedFileWriter struct {
+	w         *Writer
+	hdr       os.FileInfo
+	buf       bytes.Buffer
+	spill     *os.File
+	size      int64
+	threshold int64
+	closed    bool
+}
+
+func (b *bufferedFileWriter) Write(p []byte) (int, error) {
+	if b.spill == nil && int64(b.buf.Len())+int64(len(p)) > b.threshold {
+		f, err := os.CreateTemp("", "ar-member-*")
+		if err != nil {
+			return 0, err
+		}
+		if _, err := f.Write(b.buf.Bytes()); err != nil {
+			f.Close()
+			os.Remove(f.Name())
+			return 0, err
+		}
+		b.buf.Reset()
+		b.spill = f
+	}
+
+	if b.spill != nil {
+		n, err := b.spill.Write(p)
+		b.size += int64(n)
+		return n, err
+	}
+
+	n, err := b.buf.Write(p)
+	b.size += int64(n)
+	return n, err
+}
+
+func (b *bufferedFileWriter) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+
+	var payload io.Reader = &b.buf
+	if b.spill != nil {
+		defer os.Remove(b.spill.Name())
+		defer b.spill.Close()
+		if _, err := b.spill.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		payload = b.spill
+	}
+
+	if b.w.mode == ModeGNU {
+		data, err := ioutil.ReadAll(payload)
+		if err != nil {
+			return err
+		}
+		b.w.enqueueGNUMember(b.hdr.Name(), b.hdr.ModTime(), b.hdr.Mode(), data)
+		return nil
+	}
+
+	_, err := b.w.writeRawMember(b.hdr.Name(), b.hdr.ModTime(), b.hdr.Mode(), b.size, payload)
+	return err
+}
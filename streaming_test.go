@@ -0,0 +1,181 @@
+package ar
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCreateFileBuffered(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf)
+	hdr := &fileInfo{name: "data.tar.gz", mtime: time.Unix(1385068169, 0), mode: os.FileMode(0644)}
+
+	cw, err := w.CreateFile(hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(cw, "streamed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Close is idempotent.
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReader(buf)
+	fi, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Name() != "data.tar.gz" || fi.Size() != int64(len("streamed")) {
+		t.Errorf("got %q size %d", fi.Name(), fi.Size())
+	}
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "streamed" {
+		t.Errorf("got %q, want %q", content, "streamed")
+	}
+}
+
+func TestCreateFileSpillsToDisk(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf, WithMemoryThreshold(4))
+	hdr := &fileInfo{name: "big.bin", mtime: time.Unix(1385068169, 0), mode: os.FileMode(0644)}
+
+	cw, err := w.CreateFile(hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := bytes.Repeat([]byte{'x'}, 4096)
+	if _, err := cw.Write(payload); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReader(buf)
+	fi, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() != int64(len(payload)) {
+		t.Errorf("got size %d, want %d", fi.Size(), len(payload))
+	}
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, payload) {
+		t.Error("content mismatch")
+	}
+}
+
+func TestCreateFileKeepsGNUOrder(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf, WithMode(ModeGNU))
+
+	a := &fileInfo{name: "a.txt", mtime: time.Unix(1385068169, 0), mode: os.FileMode(0644), size: 1}
+	if _, err := w.WriteFile(a, bytes.NewReader([]byte("a"))); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &fileInfo{name: "b.txt", mtime: time.Unix(1385068169, 0), mode: os.FileMode(0644)}
+	cw, err := w.CreateFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(cw, "bb"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &fileInfo{name: "c.txt", mtime: time.Unix(1385068169, 0), mode: os.FileMode(0644), size: 1}
+	if _, err := w.WriteFile(c, bytes.NewReader([]byte("c"))); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReader(buf)
+	for _, want := range []string{"a.txt", "b.txt", "c.txt"} {
+		fi, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Name() != want {
+			t.Errorf("got name %q, want %q", fi.Name(), want)
+		}
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCreateFileSeekable(t *testing.T) {
+	tmp, err := ioutil.TempFile("", "ar-writer-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w := NewWriter(tmp)
+	hdr := &fileInfo{name: "data.tar.gz", mtime: time.Unix(1385068169, 0), mode: os.FileMode(0644)}
+
+	cw, err := w.CreateFile(hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(cw, "seekable payload"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	control := &fileInfo{name: "control", mtime: time.Unix(1385068169, 0), mode: os.FileMode(0644), size: 3}
+	if _, err := w.WriteFile(control, bytes.NewReader([]byte("abc"))); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	r := NewReader(tmp)
+	fi, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Name() != "data.tar.gz" || fi.Size() != int64(len("seekable payload")) {
+		t.Errorf("got %q size %d", fi.Name(), fi.Size())
+	}
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "seekable payload" {
+		t.Errorf("got %q", content)
+	}
+
+	fi, err = r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Name() != "control" {
+		t.Errorf("got %q, want %q", fi.Name(), "control")
+	}
+}
@@ -0,0 +1,267 @@
+package ar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// Symbol maps one exported symbol name to the byte offset of the member
+// that defines it. MemberOffset is measured from the first byte of the
+// archive, including its global magic, matching the convention used by
+// GNU ar and ranlib.
+type Symbol struct {
+	Name         string
+	MemberOffset int64
+}
+
+// SymbolTable is the parsed form of an archive's linker index: the special
+// first member ("/" for SysV/GNU, "/SYM64/" for its 64-bit variant, or
+// "__.SYMDEF"/"__.SYMDEF SORTED" for BSD) that ld and its friends require
+// to link a static archive without scanning every member.
+type SymbolTable struct {
+	symbols []Symbol
+}
+
+// Symbols returns every symbol recorded in the table, in on-disk order.
+func (st *SymbolTable) Symbols() []Symbol {
+	return st.symbols
+}
+
+var errSymbolTableAfterNext = errors.New("ar: SymbolTable must be called before Next")
+
+// SymbolTable reads and parses the archive's linker index. By convention it
+// is the very first member, so SymbolTable must be called before any call
+// to Next; it peeks at the upcoming header and, if it does not name a
+// known symbol table convention, returns NotImplementedError without
+// consuming anything, leaving Next free to return the first real member.
+func (r *Reader) SymbolTable() (*SymbolTable, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if !r.valid {
+		if err := checkMagic(r.buffer); err != nil {
+			return nil, r.stick(err)
+		}
+		r.valid = true
+	}
+	if r.section.R != nil {
+		return nil, errSymbolTableAfterNext
+	}
+
+	peek, err := r.buffer.Peek(60)
+	if err != nil {
+		return nil, r.stick(err)
+	}
+	fi, err := parseFileHeader(peek)
+	if err != nil {
+		return nil, r.stick(err)
+	}
+
+	var parse func([]byte) ([]Symbol, error)
+	switch fi.name {
+	case "/":
+		parse = parseSymbolTable32
+	case "/SYM64/":
+		parse = parseSymbolTable64
+	case "__.SYMDEF", "__.SYMDEF SORTED":
+		parse = parseBSDSymbolTable
+	default:
+		return nil, NotImplementedError("archive has no symbol table")
+	}
+
+	if _, err := r.buffer.Discard(60); err != nil {
+		return nil, r.stick(err)
+	}
+	data := make([]byte, fi.Size())
+	if _, err := io.ReadFull(r.buffer, data); err != nil {
+		return nil, r.stick(err)
+	}
+	if fi.Size()%2 != 0 {
+		if c, err := r.buffer.ReadByte(); err != nil {
+			return nil, r.stick(err)
+		} else if c != '\n' {
+			r.buffer.UnreadByte()
+		}
+	}
+
+	symbols, err := parse(data)
+	if err != nil {
+		return nil, r.stick(err)
+	}
+	return &SymbolTable{symbols: symbols}, nil
+}
+
+func parseSymbolTable32(data []byte) ([]Symbol, error) {
+	if len(data) < 4 {
+		return nil, CorruptArchiveError("symbol table truncated")
+	}
+	count := int(binary.BigEndian.Uint32(data[0:4]))
+	if count < 0 || count > (len(data)-4)/4 {
+		return nil, CorruptArchiveError("symbol table truncated")
+	}
+	need := 4 + count*4
+
+	offsets := make([]int64, count)
+	for i := 0; i < count; i++ {
+		offsets[i] = int64(binary.BigEndian.Uint32(data[4+i*4 : 8+i*4]))
+	}
+	return joinSymbolTable(offsets, data[need:])
+}
+
+func parseSymbolTable64(data []byte) ([]Symbol, error) {
+	if len(data) < 8 {
+		return nil, CorruptArchiveError("symbol table truncated")
+	}
+	count := int(binary.BigEndian.Uint64(data[0:8]))
+	if count < 0 || count > (len(data)-8)/8 {
+		return nil, CorruptArchiveError("symbol table truncated")
+	}
+	need := 8 + count*8
+
+	offsets := make([]int64, count)
+	for i := 0; i < count; i++ {
+		offsets[i] = int64(binary.BigEndian.Uint64(data[8+i*8 : 16+i*8]))
+	}
+	return joinSymbolTable(offsets, data[need:])
+}
+
+// joinSymbolTable pairs each offset with the corresponding NUL-terminated
+// name from names, in order.
+func joinSymbolTable(offsets []int64, names []byte) ([]Symbol, error) {
+	syms := make([]Symbol, len(offsets))
+	for i, off := range offsets {
+		end := bytes.IndexByte(names, 0)
+		if end < 0 {
+			return nil, CorruptArchiveError("symbol table name count mismatch")
+		}
+		syms[i] = Symbol{Name: string(names[:end]), MemberOffset: off}
+		names = names[end+1:]
+	}
+	return syms, nil
+}
+
+// parseBSDSymbolTable decodes a BSD "__.SYMDEF" / "__.SYMDEF SORTED"
+// ranlib structure table: a byte count, that many bytes of little-endian
+// {string offset, member offset} uint32 pairs, a second byte count, and
+// finally the NUL-delimited string table those pairs reference.
+func parseBSDSymbolTable(data []byte) ([]Symbol, error) {
+	if len(data) < 4 {
+		return nil, CorruptArchiveError("symbol table truncated")
+	}
+	ranlibSize := int(binary.LittleEndian.Uint32(data[0:4]))
+	pos := 4
+	if ranlibSize < 0 || ranlibSize%8 != 0 || pos+ranlibSize > len(data) {
+		return nil, CorruptArchiveError("symbol table truncated")
+	}
+	entries := data[pos : pos+ranlibSize]
+	pos += ranlibSize
+
+	if pos+4 > len(data) {
+		return nil, CorruptArchiveError("symbol table truncated")
+	}
+	strSize := int(binary.LittleEndian.Uint32(data[pos : pos+4]))
+	pos += 4
+	if strSize < 0 || pos+strSize > len(data) {
+		return nil, CorruptArchiveError("symbol table truncated")
+	}
+	strtab := data[pos : pos+strSize]
+
+	n := len(entries) / 8
+	syms := make([]Symbol, n)
+	for i := 0; i < n; i++ {
+		e := entries[i*8:]
+		strOffset := int(binary.LittleEndian.Uint32(e[0:4]))
+		memberOffset := int64(binary.LittleEndian.Uint32(e[4:8]))
+		if strOffset < 0 || strOffset > len(strtab) {
+			return nil, CorruptArchiveError("symbol table string offset out of range")
+		}
+		end := bytes.IndexByte(strtab[strOffset:], 0)
+		if end < 0 {
+			end = len(strtab) - strOffset
+		}
+		syms[i] = Symbol{Name: string(strtab[strOffset : strOffset+end]), MemberOffset: memberOffset}
+	}
+	return syms, nil
+}
+
+// Member describes a future archive member for BuildSymbolTable: enough to
+// let extractor scan its contents and to compute where it will land once
+// written.
+type Member struct {
+	Name    string
+	Mode    os.FileMode
+	ModTime time.Time
+	Data    []byte
+}
+
+// BuildSymbolTable extracts the symbols defined by each member (via
+// extractor, e.g. an ELF or Mach-O symbol scanner) and computes the
+// MemberOffset each one will end up with once WriteSymbolTable followed by
+// WriteFile for every member, in the same order, is used to write them.
+// It assumes ModeCommon encoding throughout, so member names over 16 bytes
+// are rejected.
+func BuildSymbolTable(members []Member, extractor func(io.Reader) ([]string, error)) ([]Symbol, error) {
+	memberSymbols := make([][]string, len(members))
+	nameBytes, count := 0, 0
+	for i, m := range members {
+		if len(m.Name) > 16 {
+			return nil, NotImplementedError("BuildSymbolTable only supports names up to 16 bytes")
+		}
+		names, err := extractor(bytes.NewReader(m.Data))
+		if err != nil {
+			return nil, err
+		}
+		memberSymbols[i] = names
+		for _, name := range names {
+			nameBytes += len(name) + 1
+			count++
+		}
+	}
+
+	tableSize := int64(4 + count*4 + nameBytes)
+	offset := int64(len(magic)) + 60 + tableSize + tableSize%2
+
+	syms := make([]Symbol, 0, count)
+	for i, m := range members {
+		for _, name := range memberSymbols[i] {
+			syms = append(syms, Symbol{Name: name, MemberOffset: offset})
+		}
+		size := int64(len(m.Data))
+		offset += 60 + size + size%2
+	}
+	return syms, nil
+}
+
+var errSymbolTableTooLate = errors.New("ar: WriteSymbolTable must be called before any WriteFile")
+
+// WriteSymbolTable writes syms as the archive's "/" symbol table member. It
+// must be the first thing written to a Writer: since a Writer may be
+// backed by a plain, non-seekable io.Writer, member offsets cannot be
+// patched in after the fact, so syms must already carry their final
+// MemberOffset (BuildSymbolTable computes these up front from the full set
+// of members to be written).
+func (wr *Writer) WriteSymbolTable(syms []Symbol) error {
+	if wr.wrote {
+		return errSymbolTableTooLate
+	}
+	data := encodeSymbolTable32(syms)
+	_, err := wr.writeRawMember("/", time.Unix(0, 0), 0, int64(len(data)), bytes.NewReader(data))
+	return err
+}
+
+func encodeSymbolTable32(syms []Symbol) []byte {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, uint32(len(syms)))
+	for _, s := range syms {
+		binary.Write(buf, binary.BigEndian, uint32(s.MemberOffset))
+	}
+	for _, s := range syms {
+		buf.WriteString(s.Name)
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
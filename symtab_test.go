@@ -0,0 +1,153 @@
+package ar
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func extractWords(r io.Reader) ([]string, error) {
+	var words []string
+	sc := bufio.NewScanner(r)
+	sc.Split(bufio.ScanWords)
+	for sc.Scan() {
+		words = append(words, sc.Text())
+	}
+	return words, sc.Err()
+}
+
+func TestSymbolTableRoundTrip(t *testing.T) {
+	members := []Member{
+		{Name: "a.o", Mode: 0644, ModTime: time.Unix(1385068169, 0), Data: []byte("foo bar")},
+		{Name: "b.o", Mode: 0644, ModTime: time.Unix(1385068169, 0), Data: []byte("baz")},
+	}
+
+	syms, err := BuildSymbolTable(members, extractWords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(syms) != 3 {
+		t.Fatalf("got %d symbols, want 3", len(syms))
+	}
+
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf)
+	if err := w.WriteSymbolTable(syms); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range members {
+		fi := &fileInfo{name: m.Name, mtime: m.ModTime, mode: m.Mode, size: int64(len(m.Data))}
+		if _, err := w.WriteFile(fi, bytes.NewReader(m.Data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archive := buf.Bytes()
+
+	r := NewReader(bytes.NewReader(archive))
+	st, err := r.SymbolTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := st.Symbols()
+	if len(got) != len(syms) {
+		t.Fatalf("got %d symbols, want %d", len(got), len(syms))
+	}
+	for i := range syms {
+		if got[i] != syms[i] {
+			t.Errorf("%d: got %+v, want %+v", i, got[i], syms[i])
+		}
+	}
+
+	// Next must still see the real members after the symbol table.
+	names := []string{"a.o", "b.o"}
+	for _, want := range names {
+		fi, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Name() != want {
+			t.Errorf("got name %q, want %q", fi.Name(), want)
+		}
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each symbol's MemberOffset must point at its member's actual header,
+	// relative to the start of the archive, including the global magic.
+	ir, err := NewIndexedReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerOffset := make(map[string]int64)
+	for _, fh := range ir.Files() {
+		headerOffset[fh.Name] = fh.HeaderOffset
+	}
+	memberForSymbol := map[string]string{"foo": "a.o", "bar": "a.o", "baz": "b.o"}
+	for _, s := range got {
+		want := headerOffset[memberForSymbol[s.Name]]
+		if s.MemberOffset != want {
+			t.Errorf("%s: got offset %d, want %d", s.Name, s.MemberOffset, want)
+		}
+	}
+}
+
+func TestSymbolTableAbsentIsNotImplemented(t *testing.T) {
+	r := NewReader(strings.NewReader(testCommon))
+	_, err := r.SymbolTable()
+	if _, ok := err.(NotImplementedError); !ok {
+		t.Errorf("got %v, want NotImplementedError when no symbol table is present", err)
+	}
+
+	// SymbolTable must not have consumed the first real member.
+	fi, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Name() != "debian-binary" {
+		t.Errorf("got %q, want %q", fi.Name(), "debian-binary")
+	}
+}
+
+func TestParseSymbolTableRejectsBogusCount(t *testing.T) {
+	// A count with the high bit set, parsed naively as an int, comes out
+	// negative and must not reach make([]int64, count).
+	data64 := make([]byte, 8)
+	binary.BigEndian.PutUint64(data64, 1<<63)
+	if _, err := parseSymbolTable64(data64); err == nil {
+		t.Error("expected error for /SYM64/ table with a bogus symbol count")
+	}
+
+	data32 := make([]byte, 4)
+	binary.BigEndian.PutUint32(data32, 1<<31)
+	if _, err := parseSymbolTable32(data32); err == nil {
+		t.Error("expected error for symbol table with a bogus symbol count")
+	}
+}
+
+func TestSymbolTableMustComeFirst(t *testing.T) {
+	r := NewReader(strings.NewReader(testCommon))
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.SymbolTable(); err != errSymbolTableAfterNext {
+		t.Errorf("got %v, want %v", err, errSymbolTableAfterNext)
+	}
+}
+
+func TestWriteSymbolTableMustComeFirst(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf)
+	fi := &fileInfo{name: "a.o", mtime: time.Unix(0, 0), mode: 0644, size: 0}
+	if _, err := w.WriteFile(fi, bytes.NewReader(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteSymbolTable(nil); err != errSymbolTableTooLate {
+		t.Errorf("got %v, want %v", err, errSymbolTableTooLate)
+	}
+}
@@ -0,0 +1,269 @@
+package ar
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+)
+
+// WriterMode selects how a Writer encodes member names that do not fit in
+// the classic 16 byte filename field.
+type WriterMode int
+
+const (
+	// ModeCommon only supports names up to 16 bytes. WriteFile rejects
+	// longer names unless the Writer auto-upgrades itself (see NewWriter).
+	ModeCommon WriterMode = iota
+	// ModeGNU uses the SysV/GNU "//" string table convention. Members are
+	// buffered until Close, since the table must be written before any
+	// member that references it.
+	ModeGNU
+	// ModeBSD uses the BSD "#1/N" convention, storing the real name in the
+	// first N bytes of the member's own data.
+	ModeBSD
+)
+
+// WriterOption configures a Writer created by NewWriter.
+type WriterOption func(*Writer)
+
+// WithMode selects the long filename convention a Writer uses. Without this
+// option a Writer starts in ModeCommon and auto-upgrades itself to ModeBSD
+// the first time WriteFile sees a name longer than 16 bytes, since ModeBSD
+// needs no up-front table and can be mixed freely with members already
+// written. Pick ModeGNU explicitly when the consumer needs GNU/SysV style
+// archives; its string table requires buffering every member until Close.
+func WithMode(mode WriterMode) WriterOption {
+	return func(w *Writer) {
+		w.mode = mode
+		w.modeSet = true
+	}
+}
+
+// gnuMember is a member buffered by a ModeGNU Writer until Close, once the
+// full set of long names (and therefore the string table) is known.
+type gnuMember struct {
+	name  string
+	mtime time.Time
+	mode  os.FileMode
+	data  []byte
+}
+
+// Writer writes ar archives.
+type Writer struct {
+	w       io.Writer
+	wrote   bool
+	mode    WriterMode
+	modeSet bool
+
+	// ModeGNU bookkeeping: members are buffered until Close so the "//"
+	// string table can be written before anything references it.
+	pending []gnuMember
+	offsets map[string]int64
+	table   bytes.Buffer
+
+	// memThreshold is how many bytes CreateFile buffers in memory before
+	// spilling to a temp file; see WithMemoryThreshold.
+	memThreshold    int64
+	memThresholdSet bool
+}
+
+// NewWriter creates a new archive Writer writing to w. By default it starts
+// in ModeCommon and switches to ModeBSD the first time WriteFile sees a name
+// longer than 16 bytes; use WithMode to pick a long filename convention
+// explicitly from the start.
+func NewWriter(w io.Writer, opts ...WriterOption) *Writer {
+	writer := &Writer{w: w}
+	for _, opt := range opts {
+		opt(writer)
+	}
+	return writer
+}
+
+// Reset cancels all internal state/buffering and starts to write to w.
+// Useful to avoid allocations, but otherwise has the same effect as
+// w := NewWriter(out) with the same mode.
+func (wr *Writer) Reset(w io.Writer) {
+	wr.w = w
+	wr.wrote = false
+	wr.pending = nil
+	wr.offsets = nil
+	wr.table.Reset()
+}
+
+func (wr *Writer) writeMagic() error {
+	if wr.wrote {
+		return nil
+	}
+	if _, err := io.WriteString(wr.w, magic); err != nil {
+		return err
+	}
+	wr.wrote = true
+	return nil
+}
+
+// WriteFile writes the header and content for a single archive member and
+// returns the number of bytes copied from r. fi.Size() bytes are read from
+// r regardless of how the name ends up encoded on disk.
+func (wr *Writer) WriteFile(fi os.FileInfo, r io.Reader) (int64, error) {
+	if !wr.modeSet && wr.mode == ModeCommon && len(fi.Name()) > 16 {
+		wr.mode = ModeBSD
+	}
+
+	switch wr.mode {
+	case ModeGNU:
+		return wr.writeGNUFile(fi, r)
+	case ModeBSD:
+		return wr.writeBSDFile(fi, r)
+	default:
+		return wr.writeCommonFile(fi, r)
+	}
+}
+
+func (wr *Writer) writeCommonFile(fi os.FileInfo, r io.Reader) (int64, error) {
+	if len(fi.Name()) > 16 {
+		return 0, NotImplementedError("file names longer than 16 bytes")
+	}
+	return wr.writeRawMember(fi.Name(), fi.ModTime(), fi.Mode(), fi.Size(), r)
+}
+
+// writeBSDFile stores the real name as the first len(name) bytes of the
+// member's data, with the member header using the "#1/N" convention.
+func (wr *Writer) writeBSDFile(fi os.FileInfo, r io.Reader) (int64, error) {
+	name := fi.Name()
+	bsdName := "#1/" + strconv.Itoa(len(name))
+	if len(bsdName) > 16 {
+		return 0, NotImplementedError("file name too long for BSD long name encoding")
+	}
+
+	if err := wr.writeMagic(); err != nil {
+		return 0, err
+	}
+
+	hdr := formatHeader(bsdName, fi.ModTime(), fi.Mode(), int64(len(name))+fi.Size())
+	if _, err := wr.w.Write(hdr); err != nil {
+		return 0, err
+	}
+	if _, err := io.WriteString(wr.w, name); err != nil {
+		return 0, err
+	}
+
+	n, err := io.CopyN(wr.w, r, fi.Size())
+	if err != nil {
+		return n, err
+	}
+
+	if (int64(len(name))+n)%2 != 0 {
+		if _, err := wr.w.Write([]byte{'\n'}); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// writeGNUFile buffers the member until Close, since a GNU string table
+// member must be written before anything that references it, and the set
+// of long names isn't known until every member has been seen.
+func (wr *Writer) writeGNUFile(fi os.FileInfo, r io.Reader) (int64, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, fi.Size()))
+	if err != nil {
+		return 0, err
+	}
+	wr.enqueueGNUMember(fi.Name(), fi.ModTime(), fi.Mode(), data)
+	return int64(len(data)), nil
+}
+
+// enqueueGNUMember buffers a member for Close, registering it in the "//"
+// string table first if its name needs one. Shared by writeGNUFile and
+// CreateFile, so a ModeGNU Writer keeps members in call order regardless of
+// which method produced them.
+func (wr *Writer) enqueueGNUMember(name string, mtime time.Time, mode os.FileMode, data []byte) {
+	if len(name) > 16 {
+		if wr.offsets == nil {
+			wr.offsets = make(map[string]int64)
+		}
+		if _, ok := wr.offsets[name]; !ok {
+			wr.offsets[name] = int64(wr.table.Len())
+			wr.table.WriteString(name)
+			wr.table.WriteString("/\n")
+		}
+	}
+
+	wr.pending = append(wr.pending, gnuMember{
+		name:  name,
+		mtime: mtime,
+		mode:  mode,
+		data:  data,
+	})
+}
+
+// Close flushes any members buffered for ModeGNU: the "//" string table
+// followed by every pending member, with long names rewritten to "/offset"
+// references into the table. It is a no-op for ModeCommon and ModeBSD,
+// which write each member immediately.
+func (wr *Writer) Close() error {
+	if len(wr.pending) == 0 {
+		return nil
+	}
+
+	if wr.table.Len() > 0 {
+		size := int64(wr.table.Len())
+		if _, err := wr.writeRawMember("//", time.Unix(0, 0), 0644, size, bytes.NewReader(wr.table.Bytes())); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range wr.pending {
+		name := m.name
+		if off, ok := wr.offsets[m.name]; ok {
+			name = "/" + strconv.FormatInt(off, 10)
+		}
+		if _, err := wr.writeRawMember(name, m.mtime, m.mode, int64(len(m.data)), bytes.NewReader(m.data)); err != nil {
+			return err
+		}
+	}
+
+	wr.pending = nil
+	wr.offsets = nil
+	wr.table.Reset()
+	return nil
+}
+
+func (wr *Writer) writeRawMember(name string, mtime time.Time, mode os.FileMode, size int64, r io.Reader) (int64, error) {
+	if err := wr.writeMagic(); err != nil {
+		return 0, err
+	}
+
+	hdr := formatHeader(name, mtime, mode, size)
+	if _, err := wr.w.Write(hdr); err != nil {
+		return 0, err
+	}
+
+	n, err := io.CopyN(wr.w, r, size)
+	if err != nil {
+		return n, err
+	}
+
+	if size%2 != 0 {
+		if _, err := wr.w.Write([]byte{'\n'}); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// formatHeader renders the 60 byte common file header for name, mtime,
+// mode and size, space padded exactly like the reference ar implementation.
+func formatHeader(name string, mtime time.Time, mode os.FileMode, size int64) []byte {
+	hdr := bytes.Repeat([]byte{' '}, 60)
+	copy(hdr[0:16], name)
+	copy(hdr[16:28], strconv.FormatInt(mtime.Unix(), 10))
+	copy(hdr[28:34], "0")
+	copy(hdr[34:40], "0")
+	copy(hdr[40:48], strconv.FormatUint(uint64(mode.Perm()), 8))
+	copy(hdr[48:58], strconv.FormatInt(size, 10))
+	copy(hdr[58:60], filemagic)
+	return hdr
+}